@@ -0,0 +1,203 @@
+// Package github provides a minimal client for the parts of the GitHub API
+// that Pix4D's tooling needs: authenticating as a GitHub App and minting
+// installation access tokens.
+package github
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Pix4D/go-kit/retry"
+	"github.com/Pix4D/go-kit/retry/httpretry"
+)
+
+// Retry parameters for GenerateInstallationToken. GitHub's rate-limit
+// guidance rarely exceeds a minute or two, so a short budget with
+// exponential backoff (overridden by an explicit Retry-After, see
+// httpretry.RetryAfterBackoff) is enough to ride out transient 429s and 5xxs.
+const (
+	tokenRetryUpTo         = 2 * time.Minute
+	tokenRetryFirstDelay   = 1 * time.Second
+	tokenRetryBackoffLimit = 30 * time.Second
+)
+
+// GitHubApp holds the credentials needed to authenticate as a GitHub App
+// installation.
+type GitHubApp struct {
+	// ClientId is the GitHub App's client ID, used as the JWT issuer.
+	ClientId string
+	// InstallationId is the ID of the installation to mint tokens for.
+	InstallationId int
+	// PrivateKey is the PEM-encoded private key of the GitHub App. RSA keys
+	// (PKCS#1 "RSA PRIVATE KEY" or PKCS#8 "PRIVATE KEY"), EC keys ("EC
+	// PRIVATE KEY" or PKCS#8), and Ed25519 keys (PKCS#8) are all supported.
+	PrivateKey string
+	// SigningAlgorithm overrides the JWT signing algorithm that would
+	// otherwise be auto-detected from PrivateKey's type (RS256 for RSA,
+	// ES256/ES384 for EC depending on curve, EdDSA for Ed25519). Leave empty
+	// to auto-detect.
+	SigningAlgorithm string
+}
+
+// IsZero reports whether app is the zero value.
+func (app GitHubApp) IsZero() bool {
+	return app == GitHubApp{}
+}
+
+// installationTokenResponse is the subset of GitHub's response to
+// POST /app/installations/{id}/access_tokens that we care about.
+type installationTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// GenerateInstallationToken authenticates as app and mints a new
+// installation access token by calling the GitHub API rooted at baseURL
+// (use "https://api.github.com" in production; tests can point this at a
+// httptest.Server). It retries on rate-limiting (429) and transient server
+// errors, honoring the Retry-After header GitHub sends with a 429.
+//
+// Installation tokens are valid for an hour; callers that make many
+// requests should prefer InstallationTokenSource, which caches the token
+// returned here and only re-mints it as it nears expiry.
+func GenerateInstallationToken(
+	ctx context.Context, client *http.Client, baseURL string, app GitHubApp,
+) (string, error) {
+	resp, err := requestInstallationToken(ctx, client, baseURL, app)
+	if err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+// requestInstallationToken does the work of GenerateInstallationToken but
+// returns the full response, including its expiry, for InstallationTokenSource
+// to cache.
+func requestInstallationToken(
+	ctx context.Context, client *http.Client, baseURL string, app GitHubApp,
+) (installationTokenResponse, error) {
+	appJWT, err := makeAppJWT(app)
+	if err != nil {
+		return installationTokenResponse{}, fmt.Errorf("generating app JWT: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", baseURL, app.InstallationId)
+
+	var parsed installationTokenResponse
+	rtr := retry.Retry{
+		UpTo:         tokenRetryUpTo,
+		FirstDelay:   tokenRetryFirstDelay,
+		BackoffLimit: tokenRetryBackoffLimit,
+		NextDelayFn:  httpretry.RetryAfterBackoff(retry.ExponentialBackoff),
+	}
+	err = rtr.DoContext(ctx, retry.ExponentialBackoff, httpretry.ClassifierFn,
+		func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+			if err != nil {
+				return fmt.Errorf("building request: %w", err)
+			}
+			req.Header.Set("Authorization", "Bearer "+appJWT)
+			req.Header.Set("Accept", "application/vnd.github+json")
+
+			resp, doErr := client.Do(req)
+			action, classifyErr := httpretry.Classify(resp, doErr)
+			if action != retry.Success {
+				return classifyErr
+			}
+			defer resp.Body.Close()
+
+			if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+				return fmt.Errorf("decoding response: %w", err)
+			}
+			return nil
+		})
+	if err != nil {
+		return installationTokenResponse{}, fmt.Errorf("requesting installation token: %w", err)
+	}
+	return parsed, nil
+}
+
+// makeAppJWT builds and signs the short-lived JWT GitHub requires to
+// authenticate as app. See
+// https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-a-json-web-token-jwt-for-a-github-app
+func makeAppJWT(app GitHubApp) (string, error) {
+	key, err := parsePrivateKey(app.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("parsing private key: %w", err)
+	}
+
+	method, err := signingMethodFor(key)
+	if err != nil {
+		return "", err
+	}
+	if app.SigningAlgorithm != "" {
+		method = jwt.GetSigningMethod(app.SigningAlgorithm)
+		if method == nil {
+			return "", fmt.Errorf("unknown signing algorithm %q", app.SigningAlgorithm)
+		}
+	}
+
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Issuer:    app.ClientId,
+		IssuedAt:  jwt.NewNumericDate(now.Add(-60 * time.Second)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(9 * time.Minute)),
+	}
+	return jwt.NewWithClaims(method, claims).SignedString(key)
+}
+
+// parsePrivateKey decodes a PEM-encoded private key. It accepts RSA keys in
+// PKCS#1 ("RSA PRIVATE KEY") or PKCS#8 ("PRIVATE KEY") form, EC keys in SEC1
+// ("EC PRIVATE KEY") or PKCS#8 form, and Ed25519 keys (PKCS#8 only, as there
+// is no standard PKCS#1-equivalent block for them).
+func parsePrivateKey(pemKey string) (any, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// signingMethodFor picks the JWT signing method matching key's type,
+// following GitHub's supported algorithms: RS256 for RSA, ES256/ES384 for EC
+// depending on curve, and EdDSA for Ed25519.
+func signingMethodFor(key any) (jwt.SigningMethod, error) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return jwt.SigningMethodES256, nil
+		case elliptic.P384():
+			return jwt.SigningMethodES384, nil
+		default:
+			return nil, fmt.Errorf("unsupported EC curve %s", key.Curve.Params().Name)
+		}
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
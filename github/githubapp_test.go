@@ -2,12 +2,16 @@ package github_test
 
 import (
 	"context"
+	"crypto/elliptic"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
+
 	"github.com/Pix4D/go-kit/github"
 )
 
@@ -27,7 +31,7 @@ func TestGenerateInstallationToken(t *testing.T) {
 			return
 		}
 
-		claims := decodeJWT(t, r, privateKey)
+		claims := decodeJWT(t, r, privateKey).Claims.(*jwt.RegisteredClaims)
 		if claims.Issuer != clientID {
 			w.WriteHeader(http.StatusUnauthorized)
 			fmt.Fprintln(w, "unauthorized: wrong JWT token")
@@ -58,6 +62,167 @@ func TestGenerateInstallationToken(t *testing.T) {
 	}
 }
 
+func TestGenerateInstallationTokenRetriesOnRateLimit(t *testing.T) {
+	clientID := "abcd1234"
+	installationID := 12345
+
+	privateKey := generatePrivateKey(t, 2048)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var requests int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprintln(w, "rate limited")
+			return
+		}
+
+		decodeJWT(t, r, privateKey)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintln(w, `{"token": "dummy_installation_token"}`)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	gotToken, err := github.GenerateInstallationToken(
+		ctx,
+		ts.Client(),
+		ts.URL,
+		github.GitHubApp{
+			ClientId:       clientID,
+			InstallationId: installationID,
+			PrivateKey:     string(encodePrivateKeyToPEM(privateKey)),
+		},
+	)
+	if err != nil {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "token: error", err, "<no error>")
+	}
+	if have, want := gotToken, "dummy_installation_token"; have != want {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "token", have, want)
+	}
+	if have, want := requests, 2; have != want {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "requests", have, want)
+	}
+}
+
+func TestGenerateInstallationTokenSigningAlgorithms(t *testing.T) {
+	type testCase struct {
+		name    string
+		makeKey func(t *testing.T) (pemKey string)
+		wantAlg string
+	}
+
+	testCases := []testCase{
+		{
+			name: "RSA, PKCS#1 PEM",
+			makeKey: func(t *testing.T) string {
+				return string(encodePrivateKeyToPEM(generatePrivateKey(t, 2048)))
+			},
+			wantAlg: "RS256",
+		},
+		{
+			name: "RSA, PKCS#8 PEM",
+			makeKey: func(t *testing.T) string {
+				return string(encodePKCS8PEM(t, generatePrivateKey(t, 2048)))
+			},
+			wantAlg: "RS256",
+		},
+		{
+			name: "ECDSA P-256, EC PEM",
+			makeKey: func(t *testing.T) string {
+				return string(encodeECKeyToPEM(t, generateECKey(t, elliptic.P256())))
+			},
+			wantAlg: "ES256",
+		},
+		{
+			name: "ECDSA P-384, PKCS#8 PEM",
+			makeKey: func(t *testing.T) string {
+				return string(encodePKCS8PEM(t, generateECKey(t, elliptic.P384())))
+			},
+			wantAlg: "ES384",
+		},
+		{
+			name: "Ed25519, PKCS#8 PEM",
+			makeKey: func(t *testing.T) string {
+				return string(encodePKCS8PEM(t, generateEd25519Key(t)))
+			},
+			wantAlg: "EdDSA",
+		},
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		pemKey := tc.makeKey(t)
+
+		var gotAlg string
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			auth := strings.Fields(r.Header.Get("Authorization"))[1]
+			tok, _, err := jwt.NewParser().ParseUnverified(auth, &jwt.RegisteredClaims{})
+			if err != nil {
+				t.Fatal("parsing JWT header:", err)
+			}
+			gotAlg, _ = tok.Header["alg"].(string)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintln(w, `{"token": "dummy_installation_token"}`)
+		}
+		ts := httptest.NewServer(http.HandlerFunc(handler))
+		defer ts.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		_, err := github.GenerateInstallationToken(ctx, ts.Client(), ts.URL, github.GitHubApp{
+			ClientId:   "abcd1234",
+			PrivateKey: pemKey,
+		})
+		if err != nil {
+			t.Fatalf("%s\nhave: %v\nwant: %v", "token: error", err, "<no error>")
+		}
+		if have, want := gotAlg, tc.wantAlg; have != want {
+			t.Errorf("%s\nhave: %v\nwant: %v", "alg header", have, want)
+		}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { run(t, tc) })
+	}
+}
+
+func TestGenerateInstallationTokenSigningAlgorithmOverride(t *testing.T) {
+	// RS512 differs from the RS256 auto-detected for an RSA key, so the
+	// override must take effect for the test to pass.
+	key := generatePrivateKey(t, 2048)
+
+	var gotAlg string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		tok := decodeJWT(t, r, key)
+		gotAlg, _ = tok.Header["alg"].(string)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintln(w, `{"token": "dummy_installation_token"}`)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := github.GenerateInstallationToken(ctx, ts.Client(), ts.URL, github.GitHubApp{
+		ClientId:         "abcd1234",
+		PrivateKey:       string(encodePrivateKeyToPEM(key)),
+		SigningAlgorithm: "RS512",
+	})
+	if err != nil {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "token: error", err, "<no error>")
+	}
+	if have, want := gotAlg, "RS512"; have != want {
+		t.Errorf("%s\nhave: %v\nwant: %v", "alg header", have, want)
+	}
+}
+
 func TestGitHubAppIsZero(t *testing.T) {
 	type testCase struct {
 		name string
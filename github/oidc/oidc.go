@@ -0,0 +1,210 @@
+// Package oidc lets a GitHub Actions job exchange its OIDC ID token for
+// short-lived credentials from an external identity provider (RFC 8693
+// token exchange), so that workflows can authenticate to clouds without
+// holding a long-lived secret. This complements github.GenerateInstallationToken
+// by giving Actions-based callers a keyless alternative.
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// githubIssuer is the OIDC issuer for every GitHub Actions ID token.
+const githubIssuer = "https://token.actions.githubusercontent.com"
+
+// RequestIDToken obtains the current GitHub Actions job's OIDC ID token,
+// scoped to audience, from the Actions runtime's token endpoint. It requires
+// the ACTIONS_ID_TOKEN_REQUEST_URL and ACTIONS_ID_TOKEN_REQUEST_TOKEN
+// environment variables, which Actions sets automatically when the job's
+// id-token permission is "write". See
+// https://docs.github.com/en/actions/security-guides/security-hardening-your-deployments/about-security-hardening-with-openid-connect
+func RequestIDToken(ctx context.Context, audience string) (string, error) {
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return "", fmt.Errorf(
+			"not running in a GitHub Actions job with the id-token permission: " +
+				"ACTIONS_ID_TOKEN_REQUEST_URL or ACTIONS_ID_TOKEN_REQUEST_TOKEN is not set")
+	}
+
+	sep := "&"
+	if !strings.Contains(requestURL, "?") {
+		sep = "?"
+	}
+	fullURL := requestURL + sep + "audience=" + url.QueryEscape(audience)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+requestToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("requesting ID token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+	}
+
+	var parsed struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "", fmt.Errorf("decoding response: %w", err)
+	}
+	return parsed.Value, nil
+}
+
+// Credentials are the short-lived credentials an Exchanger returns.
+type Credentials struct {
+	AccessToken     string
+	IssuedTokenType string
+	TokenType       string
+	ExpiresIn       time.Duration
+}
+
+// Exchanger exchanges subjectToken (typically the ID token from
+// RequestIDToken) for Credentials from an external identity provider.
+type Exchanger interface {
+	Exchange(ctx context.Context, subjectToken string) (Credentials, error)
+}
+
+// STSExchanger implements Exchanger against an RFC 8693 token-exchange
+// endpoint, POSTing the request as application/x-www-form-urlencoded. This
+// is the shape used by cloud providers' OIDC federation token endpoints.
+type STSExchanger struct {
+	// Endpoint is the token-exchange URL.
+	Endpoint string
+	// Audience, if set, is sent as the RFC 8693 "audience" parameter.
+	Audience string
+	// Scope, if set, is sent as the RFC 8693 "scope" parameter.
+	Scope string
+	// Client performs the HTTP call. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Exchange implements Exchanger.
+func (e STSExchanger) Exchange(ctx context.Context, subjectToken string) (Credentials, error) {
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	form := url.Values{
+		"grant_type":         {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"subject_token":      {subjectToken},
+		"subject_token_type": {"urn:ietf:params:oauth:token-type:jwt"},
+	}
+	if e.Audience != "" {
+		form.Set("audience", e.Audience)
+	}
+	if e.Scope != "" {
+		form.Set("scope", e.Scope)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, e.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("exchanging token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("reading response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+	}
+
+	var parsed struct {
+		AccessToken     string `json:"access_token"`
+		IssuedTokenType string `json:"issued_token_type"`
+		TokenType       string `json:"token_type"`
+		ExpiresIn       int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return Credentials{}, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return Credentials{
+		AccessToken:     parsed.AccessToken,
+		IssuedTokenType: parsed.IssuedTokenType,
+		TokenType:       parsed.TokenType,
+		ExpiresIn:       time.Duration(parsed.ExpiresIn) * time.Second,
+	}, nil
+}
+
+// Claims are the GitHub Actions OIDC claims relevant to gating a token
+// exchange on a trusted workflow. See
+// https://docs.github.com/en/actions/security-guides/security-hardening-your-deployments/about-security-hardening-with-openid-connect#understanding-the-oidc-token
+type Claims struct {
+	Issuer     string `json:"iss"`
+	Subject    string `json:"sub"`
+	Audience   string `json:"aud"`
+	Repository string `json:"repository"`
+	Ref        string `json:"ref"`
+}
+
+// VerifyClaims decodes idToken's claims and checks that its issuer is
+// GitHub Actions', and that its repository and ref satisfy the
+// caller-supplied predicates, so that an Exchanger is only invoked for
+// workflows the caller trusts. A nil predicate is treated as always
+// satisfied.
+//
+// VerifyClaims does not verify idToken's signature: it only inspects the
+// claims carried by a token the caller already obtained from
+// RequestIDToken (or otherwise trusts the transport of). Callers that
+// accept ID tokens from elsewhere must verify the signature against
+// GitHub's JWKS (https://token.actions.githubusercontent.com/.well-known/jwks)
+// themselves before calling VerifyClaims.
+func VerifyClaims(idToken string, trustRepository, trustRef func(string) bool) (Claims, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("malformed ID token: expected 3 parts, got %d", len(parts))
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return Claims{}, fmt.Errorf("decoding ID token payload: %w", err)
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Claims{}, fmt.Errorf("decoding ID token claims: %w", err)
+	}
+
+	if claims.Issuer != githubIssuer {
+		return Claims{}, fmt.Errorf("unexpected issuer %q, want %q", claims.Issuer, githubIssuer)
+	}
+	if trustRepository != nil && !trustRepository(claims.Repository) {
+		return Claims{}, fmt.Errorf("repository %q is not trusted", claims.Repository)
+	}
+	if trustRef != nil && !trustRef(claims.Ref) {
+		return Claims{}, fmt.Errorf("ref %q is not trusted", claims.Ref)
+	}
+	return claims, nil
+}
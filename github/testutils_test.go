@@ -3,11 +3,14 @@
 package github_test
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
-	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -101,25 +104,79 @@ func encodePrivateKeyToPEM(privateKey *rsa.PrivateKey) []byte {
 	return pem.EncodeToMemory(&privBlock)
 }
 
-// decodeJWT decodes the HTTP request authorization header with the given RSA key
-// and returns the registered claims of the decoded token.
-func decodeJWT(t *testing.T, r *http.Request, key *rsa.PrivateKey) *jwt.RegisteredClaims {
+// generateECKey generates an ECDSA private key on the given curve.
+func generateECKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
 	t.Helper()
 
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal("generating EC private key:", err)
+	}
+	return key
+}
+
+// generateEd25519Key generates an Ed25519 private key.
+func generateEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal("generating Ed25519 private key:", err)
+	}
+	return key
+}
+
+// encodeECKeyToPEM encodes an EC private key as an "EC PRIVATE KEY" (SEC1) PEM block.
+func encodeECKeyToPEM(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal("marshaling EC private key:", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+// encodePKCS8PEM encodes any of *rsa.PrivateKey, *ecdsa.PrivateKey or
+// ed25519.PrivateKey as a "PRIVATE KEY" (PKCS#8) PEM block.
+func encodePKCS8PEM(t *testing.T, key any) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal("marshaling PKCS#8 private key:", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// publicKeyOf returns the public half of an *rsa.PrivateKey, *ecdsa.PrivateKey
+// or ed25519.PrivateKey.
+func publicKeyOf(t *testing.T, key any) crypto.PublicKey {
+	t.Helper()
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		t.Fatalf("key of type %T is not a crypto.Signer", key)
+	}
+	return signer.Public()
+}
+
+// decodeJWT decodes the HTTP request's Authorization header, verifying it
+// against the public half of key (an *rsa.PrivateKey, *ecdsa.PrivateKey or
+// ed25519.PrivateKey), and returns the decoded token so that callers can
+// inspect both its header (e.g. the "alg" used to sign it) and its claims.
+func decodeJWT(t *testing.T, r *http.Request, key any) *jwt.Token {
+	t.Helper()
+
+	publicKey := publicKeyOf(t, key)
 	token := strings.Fields(r.Header.Get("Authorization"))[1]
 	tok, err := jwt.ParseWithClaims(token, &jwt.RegisteredClaims{},
-		func(tk *jwt.Token) (any, error) {
-			if tk.Header["alg"] != "RS256" {
-				return nil, fmt.Errorf("unexpected signing method: %v, expected: %v",
-					tk.Header["alg"], "RS256")
-			}
-			return &key.PublicKey, nil
-		})
+		func(tk *jwt.Token) (any, error) { return publicKey, nil })
 	if err != nil {
 		t.Fatal("parsing JWT claims:", err)
 	}
 
-	return tok.Claims.(*jwt.RegisteredClaims)
+	return tok
 }
 
 // makeTestLog returns a *slog.Logger adapted for tests: it never reports the
@@ -0,0 +1,116 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultRenewBefore is the default value of InstallationTokenSource.RenewBefore.
+const defaultRenewBefore = 5 * time.Minute
+
+// InstallationTokenSource caches a GitHub App installation token and
+// re-mints it only once it is within RenewBefore of expiring, instead of
+// minting a new token (and spending a JWT) on every call.
+type InstallationTokenSource struct {
+	// App authenticates the installation to mint tokens for.
+	App GitHubApp
+	// BaseURL is the GitHub API root, e.g. "https://api.github.com".
+	BaseURL string
+	// Client performs the HTTP calls used to mint a token. Defaults to
+	// http.DefaultClient.
+	Client *http.Client
+	// Clock returns the current time; defaults to time.Now. Tests override
+	// it to simulate token expiry without waiting an hour.
+	Clock func() time.Time
+	// RenewBefore is how long before expiry a cached token is renewed.
+	// Defaults to 5 minutes.
+	RenewBefore time.Duration
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// Token returns a valid installation token and its expiry, minting a new one
+// if none is cached yet or the cached one is within RenewBefore of expiring.
+func (s *InstallationTokenSource) Token(ctx context.Context) (string, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Before(s.expiresAt.Add(-s.renewBefore())) {
+		return s.token, s.expiresAt, nil
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := requestInstallationToken(ctx, client, s.BaseURL, s.App)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("minting installation token: %w", err)
+	}
+
+	s.token, s.expiresAt = resp.Token, resp.ExpiresAt
+	return s.token, s.expiresAt, nil
+}
+
+// HTTPClient returns an *http.Client whose RoundTripper injects a valid
+// installation token into the Authorization header of every outgoing
+// request, minting or renewing it via Token as needed. This mirrors the
+// oauth2.TokenSource / oauth2.NewClient shape.
+func (s *InstallationTokenSource) HTTPClient(ctx context.Context) *http.Client {
+	base := s.Client
+	if base == nil {
+		base = http.DefaultClient
+	}
+	return &http.Client{
+		Timeout: base.Timeout,
+		Transport: &installationTokenTransport{
+			source: s,
+			ctx:    ctx,
+			base:   base.Transport,
+		},
+	}
+}
+
+func (s *InstallationTokenSource) now() time.Time {
+	if s.Clock != nil {
+		return s.Clock()
+	}
+	return time.Now()
+}
+
+func (s *InstallationTokenSource) renewBefore() time.Duration {
+	if s.RenewBefore == 0 {
+		return defaultRenewBefore
+	}
+	return s.RenewBefore
+}
+
+// installationTokenTransport is an http.RoundTripper that authenticates
+// every request as a GitHub App installation, delegating the actual
+// round trip to base (http.DefaultTransport if nil).
+type installationTokenTransport struct {
+	source *InstallationTokenSource
+	ctx    context.Context
+	base   http.RoundTripper
+}
+
+func (t *installationTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, _, err := t.source.Token(t.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting installation token: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+token)
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
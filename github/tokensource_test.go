@@ -0,0 +1,114 @@
+package github_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Pix4D/go-kit/github"
+)
+
+func TestInstallationTokenSourceCachesUntilRenewBefore(t *testing.T) {
+	privateKey := generatePrivateKey(t, 2048)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var requests int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": "token-%d", "expires_at": %q}`,
+			requests, now.Add(time.Hour).Format(time.RFC3339))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	defer ts.Close()
+
+	src := &github.InstallationTokenSource{
+		App: github.GitHubApp{
+			ClientId:   "abcd1234",
+			PrivateKey: string(encodePrivateKeyToPEM(privateKey)),
+		},
+		BaseURL:     ts.URL,
+		Client:      ts.Client(),
+		Clock:       func() time.Time { return now },
+		RenewBefore: 5 * time.Minute,
+	}
+
+	ctx := context.Background()
+	gotToken, gotExpiry, err := src.Token(ctx)
+	if err != nil {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "Token: error", err, "<no error>")
+	}
+	if have, want := gotToken, "token-1"; have != want {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "token", have, want)
+	}
+	if have, want := gotExpiry, now.Add(time.Hour); !have.Equal(want) {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "expiry", have, want)
+	}
+
+	// Still well within validity: no new request made, same token returned.
+	gotToken, _, err = src.Token(ctx)
+	if err != nil {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "Token: error", err, "<no error>")
+	}
+	if have, want := gotToken, "token-1"; have != want {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "cached token", have, want)
+	}
+	if have, want := requests, 1; have != want {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "requests", have, want)
+	}
+
+	// Advance the clock into the renewal window: a new token is minted.
+	now = now.Add(56 * time.Minute)
+	gotToken, _, err = src.Token(ctx)
+	if err != nil {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "Token: error", err, "<no error>")
+	}
+	if have, want := gotToken, "token-2"; have != want {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "renewed token", have, want)
+	}
+	if have, want := requests, 2; have != want {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "requests", have, want)
+	}
+}
+
+func TestInstallationTokenSourceHTTPClientInjectsAuthorization(t *testing.T) {
+	privateKey := generatePrivateKey(t, 2048)
+
+	var gotAuth string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app/installations/0/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": "dummy_installation_token", "expires_at": %q}`,
+			time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	mux.HandleFunc("/some/api", func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	src := &github.InstallationTokenSource{
+		App: github.GitHubApp{
+			ClientId:   "abcd1234",
+			PrivateKey: string(encodePrivateKeyToPEM(privateKey)),
+		},
+		BaseURL: ts.URL,
+		Client:  ts.Client(),
+	}
+
+	ctx := context.Background()
+	client := src.HTTPClient(ctx)
+	resp, err := client.Get(ts.URL + "/some/api")
+	if err != nil {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "GET: error", err, "<no error>")
+	}
+	resp.Body.Close()
+
+	if have, want := gotAuth, "token dummy_installation_token"; have != want {
+		t.Fatalf("%s\nhave: %v\nwant: %v", "Authorization header", have, want)
+	}
+}
@@ -0,0 +1,137 @@
+// Package httpretry adapts package retry to HTTP calls: it classifies HTTP
+// responses into retry.Action values and honors the Retry-After header that
+// rate-limited APIs such as GitHub's return, instead of guessing a backoff.
+package httpretry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Pix4D/go-kit/retry"
+)
+
+// HTTPError wraps a non-success HTTP response, so that callers and
+// RetryAfterBackoff can inspect its status code, Retry-After delay and body
+// after the response has already been read and closed.
+type HTTPError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Header     http.Header
+	Body       []byte
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP request failed: %s: %s", http.StatusText(e.StatusCode), e.Body)
+}
+
+// Classify turns the result of an HTTP round trip into a retry.Action.
+// A transport-level err (connection refused, timeout, ...) is a SoftFail.
+// 4xx responses are a HardFail, except 408 (Request Timeout), 425 (Too
+// Early) and 429 (Too Many Requests), which are retried, and 403 (Forbidden)
+// when it carries X-Ratelimit-Remaining: 0 or a Retry-After header, which
+// GitHub uses for its secondary rate limit. 5xx responses are a SoftFail.
+// Anything else is a Success.
+//
+// On a non-success status, Classify reads and closes resp.Body and returns
+// it as part of the *HTTPError. On success, resp.Body is left open for the
+// caller to read and close, since the response payload is useful to them.
+func Classify(resp *http.Response, err error) (retry.Action, error) {
+	if err != nil {
+		return retry.SoftFail, err
+	}
+	if resp.StatusCode < 400 {
+		return retry.Success, nil
+	}
+	defer resp.Body.Close()
+
+	body, readErr := io.ReadAll(resp.Body)
+	if readErr != nil {
+		body = []byte(fmt.Sprintf("<reading body: %s>", readErr))
+	}
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header),
+		Header:     resp.Header,
+		Body:       body,
+	}
+
+	if isSoftFail(resp.StatusCode, resp.Header) {
+		return retry.SoftFail, httpErr
+	}
+	return retry.HardFail, httpErr
+}
+
+// ClassifierFn is a retry.ClassifierFn built on top of Classify: it treats a
+// nil error as Success, an *HTTPError as SoftFail or HardFail according to
+// its status code, and any other non-nil error (e.g. a transport failure) as
+// SoftFail. Use it as the classify argument to retry.Retry.DoContext when
+// the work function itself calls Classify and returns the resulting error.
+func ClassifierFn(err error) retry.Action {
+	if err == nil {
+		return retry.Success
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		if isSoftFail(httpErr.StatusCode, httpErr.Header) {
+			return retry.SoftFail
+		}
+		return retry.HardFail
+	}
+	return retry.SoftFail
+}
+
+// isSoftFail reports whether status is worth retrying. 403 is only worth
+// retrying when header indicates it is GitHub's secondary rate limit
+// (X-Ratelimit-Remaining: 0 or a Retry-After hint) rather than a genuine
+// authorization failure, which retrying would not fix.
+func isSoftFail(status int, header http.Header) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return header.Get("X-Ratelimit-Remaining") == "0" || header.Get("Retry-After") != ""
+	default:
+		return status >= 500
+	}
+}
+
+// parseRetryAfter parses the Retry-After header, in either its
+// delay-in-seconds or HTTP-date form. It returns 0 if the header is absent
+// or malformed.
+func parseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when)
+	}
+	return 0
+}
+
+// RetryAfterBackoff returns a retry.Retry.NextDelayFn that, when the error
+// from the last attempt is an *HTTPError carrying a positive RetryAfter,
+// waits that long, capped at limit, honoring the server's rate-limit guidance
+// instead of guessing. For any other error, or a non-positive RetryAfter, it
+// delegates to fallback, so normal exponential/jitter backoff still applies.
+func RetryAfterBackoff(
+	fallback retry.BackoffFn,
+) func(err error, attempt int, first, limit time.Duration) time.Duration {
+	return func(err error, attempt int, first, limit time.Duration) time.Duration {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+			if httpErr.RetryAfter > limit {
+				return limit
+			}
+			return httpErr.RetryAfter
+		}
+		return fallback(attempt, first, limit)
+	}
+}
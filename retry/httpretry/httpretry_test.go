@@ -0,0 +1,140 @@
+package httpretry_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Pix4D/go-kit/retry"
+	"github.com/Pix4D/go-kit/retry/httpretry"
+)
+
+func TestClassify(t *testing.T) {
+	type testCase struct {
+		name       string
+		statusCode int
+		header     http.Header
+		wantAction retry.Action
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		resp := httptest.NewRecorder()
+		resp.Code = tc.statusCode
+		for key, values := range tc.header {
+			for _, v := range values {
+				resp.Header().Add(key, v)
+			}
+		}
+		resp.Body.WriteString("body")
+
+		action, err := httpretry.Classify(resp.Result(), nil)
+
+		if have, want := action, tc.wantAction; have != want {
+			t.Errorf("%s:\nhave: %v\nwant: %v", "action", have, want)
+		}
+		if tc.wantAction == retry.Success {
+			if err != nil {
+				t.Errorf("%s:\nhave: %v\nwant: %v", "error", err, "<no error>")
+			}
+			return
+		}
+		var httpErr *httpretry.HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("error is not a *httpretry.HTTPError: %v", err)
+		}
+		if have, want := httpErr.StatusCode, tc.statusCode; have != want {
+			t.Errorf("%s:\nhave: %v\nwant: %v", "HTTPError.StatusCode", have, want)
+		}
+	}
+
+	testCases := []testCase{
+		{name: "200 is a success", statusCode: http.StatusOK, wantAction: retry.Success},
+		{name: "400 is a hard fail", statusCode: http.StatusBadRequest, wantAction: retry.HardFail},
+		{name: "404 is a hard fail", statusCode: http.StatusNotFound, wantAction: retry.HardFail},
+		{name: "plain 403 is a hard fail", statusCode: http.StatusForbidden, wantAction: retry.HardFail},
+		{
+			name:       "403 with X-Ratelimit-Remaining: 0 is a soft fail",
+			statusCode: http.StatusForbidden,
+			header:     http.Header{"X-Ratelimit-Remaining": {"0"}},
+			wantAction: retry.SoftFail,
+		},
+		{
+			name:       "403 with Retry-After is a soft fail",
+			statusCode: http.StatusForbidden,
+			header:     http.Header{"Retry-After": {"30"}},
+			wantAction: retry.SoftFail,
+		},
+		{name: "408 is a soft fail", statusCode: http.StatusRequestTimeout, wantAction: retry.SoftFail},
+		{name: "429 is a soft fail", statusCode: http.StatusTooManyRequests, wantAction: retry.SoftFail},
+		{name: "500 is a soft fail", statusCode: http.StatusInternalServerError, wantAction: retry.SoftFail},
+		{name: "503 is a soft fail", statusCode: http.StatusServiceUnavailable, wantAction: retry.SoftFail},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { run(t, tc) })
+	}
+}
+
+func TestClassifyTransportError(t *testing.T) {
+	wantErr := errors.New("connection refused")
+
+	action, err := httpretry.Classify(nil, wantErr)
+
+	if have, want := action, retry.SoftFail; have != want {
+		t.Errorf("%s:\nhave: %v\nwant: %v", "action", have, want)
+	}
+	if !errors.Is(err, wantErr) {
+		t.Errorf("%s:\nhave: %v\nwant: %v", "error", err, wantErr)
+	}
+}
+
+func TestClassifyParsesRetryAfterSeconds(t *testing.T) {
+	resp := httptest.NewRecorder()
+	resp.Code = http.StatusTooManyRequests
+	resp.Header().Set("Retry-After", "30")
+
+	_, err := httpretry.Classify(resp.Result(), nil)
+
+	var httpErr *httpretry.HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("error is not a *httpretry.HTTPError: %v", err)
+	}
+	if have, want := httpErr.RetryAfter, 30*time.Second; have != want {
+		t.Errorf("%s:\nhave: %v\nwant: %v", "HTTPError.RetryAfter", have, want)
+	}
+}
+
+func TestRetryAfterBackoffHonorsRetryAfter(t *testing.T) {
+	fallback := func(attempt int, first, limit time.Duration) time.Duration {
+		t.Fatalf("fallback should not be called when RetryAfter is set")
+		return 0
+	}
+	backoff := httpretry.RetryAfterBackoff(fallback)
+
+	err := &httpretry.HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 10 * time.Second}
+
+	if have, want := backoff(err, 0, time.Second, time.Minute), 10*time.Second; have != want {
+		t.Errorf("%s:\nhave: %v\nwant: %v", "delay", have, want)
+	}
+}
+
+func TestRetryAfterBackoffCapsAtLimit(t *testing.T) {
+	backoff := httpretry.RetryAfterBackoff(retry.ConstantBackoff)
+	err := &httpretry.HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Hour}
+
+	if have, want := backoff(err, 0, time.Second, time.Minute), time.Minute; have != want {
+		t.Errorf("%s:\nhave: %v\nwant: %v", "delay", have, want)
+	}
+}
+
+func TestRetryAfterBackoffFallsBackWithoutRetryAfter(t *testing.T) {
+	backoff := httpretry.RetryAfterBackoff(retry.ExponentialBackoff)
+	ErrOther := errors.New("some other error")
+
+	have := backoff(ErrOther, 2, time.Second, time.Minute)
+	want := retry.ExponentialBackoff(2, time.Second, time.Minute)
+	if have != want {
+		t.Errorf("%s:\nhave: %v\nwant: %v", "delay", have, want)
+	}
+}
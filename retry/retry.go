@@ -0,0 +1,211 @@
+// Package retry implements a small, dependency-free retry loop with
+// pluggable backoff strategies and failure classification.
+package retry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// Action tells Do how to proceed after evaluating the outcome of an attempt.
+type Action int
+
+const (
+	// Success means the operation succeeded; Do returns immediately with a
+	// nil error.
+	Success Action = iota
+	// SoftFail means the operation failed but a subsequent attempt might
+	// succeed; Do will sleep and retry, budget permitting.
+	SoftFail
+	// HardFail means the operation failed in a way that retrying will not
+	// fix; Do returns immediately with the error.
+	HardFail
+)
+
+// ClassifierFn inspects the error returned by the work function and decides
+// whether Do should retry, give up, or consider the attempt successful.
+type ClassifierFn func(err error) Action
+
+// BackoffFn computes the delay to sleep before the next attempt, given the
+// zero-based attempt number, the delay of the first attempt and the maximum
+// allowed delay.
+type BackoffFn func(attempt int, first, limit time.Duration) time.Duration
+
+// ConstantBackoff always waits for first, ignoring attempt and limit.
+func ConstantBackoff(attempt int, first, limit time.Duration) time.Duration {
+	return first
+}
+
+// ExponentialBackoff doubles the delay at each attempt, capped at limit.
+func ExponentialBackoff(attempt int, first, limit time.Duration) time.Duration {
+	delay := first << attempt
+	if delay <= 0 || delay > limit {
+		return limit
+	}
+	return delay
+}
+
+// FullJitterBackoff picks a delay uniformly at random between 0 and the
+// exponential backoff ceiling (first<<attempt, capped at limit). Unlike
+// ExponentialBackoff, concurrent callers sharing the same attempt number
+// don't all wake up at the same time, which avoids thundering-herd retries
+// against rate-limited APIs.
+//
+// FullJitterBackoff is a method on Retry, not a package-level BackoffFn,
+// because it needs its own source of randomness: use it as
+// rtr.FullJitterBackoff, which draws from rtr.Rand (or an independent source
+// seeded from the clock, if Rand is nil) rather than state shared across
+// concurrent callers.
+func (r Retry) FullJitterBackoff(attempt int, first, limit time.Duration) time.Duration {
+	ceiling := first << attempt
+	if ceiling <= 0 || ceiling > limit {
+		ceiling = limit
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(r.randSource().Int63n(int64(ceiling)))
+}
+
+// NewDecorrelatedJitterBackoff returns a BackoffFn implementing the
+// "decorrelated jitter" strategy (see
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/):
+// each delay is chosen uniformly at random between first and three times the
+// previous delay, capped at limit. The returned function carries its
+// previous delay, and the *rand.Rand it draws from (r.Rand, or an
+// independent source if r.Rand is nil), across calls via a closure, so each
+// call to NewDecorrelatedJitterBackoff gets its own isolated state: it is
+// only safe to use the returned BackoffFn for a single, sequential Retry.Do
+// call.
+func (r Retry) NewDecorrelatedJitterBackoff() BackoffFn {
+	rnd := r.randSource()
+	var prev time.Duration
+
+	return func(attempt int, first, limit time.Duration) time.Duration {
+		if prev == 0 {
+			prev = first
+		}
+
+		span := prev*3 - first
+		if span <= 0 {
+			span = 1
+		}
+		delay := time.Duration(rnd.Int63n(int64(span))) + first
+		if delay > limit {
+			delay = limit
+		}
+		prev = delay
+		return delay
+	}
+}
+
+// randSource returns r.Rand, or an independent source seeded from the clock
+// if r.Rand is nil. Each caller with a nil Rand gets its own *rand.Rand, so
+// concurrent Retry calls using the jitter backoffs never share mutable
+// state: rand.Rand is not safe for concurrent use.
+func (r Retry) randSource() *rand.Rand {
+	if r.Rand != nil {
+		return r.Rand
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// Retry implements a retry loop with a pluggable backoff strategy.
+type Retry struct {
+	// UpTo is the maximum cumulative time spent sleeping between attempts.
+	UpTo time.Duration
+	// FirstDelay is the delay used for the first retry.
+	FirstDelay time.Duration
+	// BackoffLimit caps the delay computed by BackoffFn.
+	BackoffLimit time.Duration
+	// Log receives one informational entry per retry attempt.
+	Log *slog.Logger
+	// SleepFn is called to wait between attempts; tests set it to a function
+	// recording the requested delays. If nil, DoContext waits on a timer that
+	// ctx's cancellation interrupts; a non-nil SleepFn is not interrupted.
+	SleepFn func(d time.Duration)
+	// Rand, if set, is the source of randomness used by FullJitterBackoff
+	// and NewDecorrelatedJitterBackoff, so that tests can inject a
+	// deterministic source. If nil, each of those methods draws from its
+	// own independent *rand.Rand seeded from the clock.
+	Rand *rand.Rand
+	// NextDelayFn, if set, computes the delay before the next attempt from
+	// the error returned by the last attempt, instead of BackoffFn. This
+	// lets callers honor a hint carried by a typed error (for example an
+	// HTTP Retry-After header) while still falling back to a BackoffFn for
+	// errors that carry no such hint; see httpretry.RetryAfterBackoff.
+	NextDelayFn func(err error, attempt int, first, limit time.Duration) time.Duration
+}
+
+// Do calls work repeatedly, sleeping according to backoff between attempts,
+// until classify reports Success or HardFail, or the cumulative sleep time
+// would exceed UpTo. It returns the error of the last attempt, or nil on
+// success.
+//
+// Do is a thin wrapper around DoContext using context.Background(), so work
+// receives no cancellation signal and the retry loop cannot be aborted early.
+func (r Retry) Do(backoff BackoffFn, classify ClassifierFn, work func() error) error {
+	return r.DoContext(context.Background(), backoff, classify,
+		func(context.Context) error { return work() })
+}
+
+// DoContext behaves like Do but threads ctx through to work and aborts as
+// soon as ctx is cancelled, instead of waiting for the current sleep to
+// finish. If ctx carries a deadline earlier than UpTo would otherwise allow,
+// that deadline caps the total time DoContext is willing to spend retrying.
+// On cancellation, the returned error joins the last error from work (if
+// any) with ctx.Err().
+func (r Retry) DoContext(
+	ctx context.Context, backoff BackoffFn, classify ClassifierFn,
+	work func(context.Context) error,
+) error {
+	upTo := r.UpTo
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < upTo {
+			upTo = remaining
+		}
+	}
+
+	var elapsed time.Duration
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(lastErr, err)
+		}
+
+		lastErr = work(ctx)
+		switch classify(lastErr) {
+		case Success:
+			return nil
+		case HardFail:
+			return lastErr
+		}
+
+		delay := backoff(attempt, r.FirstDelay, r.BackoffLimit)
+		if r.NextDelayFn != nil {
+			delay = r.NextDelayFn(lastErr, attempt, r.FirstDelay, r.BackoffLimit)
+		}
+		if elapsed+delay > upTo {
+			return lastErr
+		}
+
+		if r.Log != nil {
+			r.Log.Info("retry: attempt failed, sleeping before next attempt",
+				"attempt", attempt, "delay", delay, "error", lastErr)
+		}
+
+		if r.SleepFn != nil {
+			r.SleepFn(delay)
+		} else {
+			select {
+			case <-ctx.Done():
+				return errors.Join(lastErr, ctx.Err())
+			case <-time.After(delay):
+			}
+		}
+		elapsed += delay
+	}
+}
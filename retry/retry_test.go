@@ -1,12 +1,15 @@
 package retry_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math/rand"
 	"os"
 	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -150,6 +153,187 @@ func TestRetryFailureHardFailOnSecondAttempt(t *testing.T) {
 	}
 }
 
+func TestFullJitterBackoffWithinBounds(t *testing.T) {
+	type testCase struct {
+		name         string
+		attempt      int
+		first, limit time.Duration
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		rtr := retry.Retry{Rand: rand.New(rand.NewSource(42))}
+		ceiling := tc.first << tc.attempt
+		if ceiling <= 0 || ceiling > tc.limit {
+			ceiling = tc.limit
+		}
+
+		var got time.Duration
+		rtr.Do(retry.ConstantBackoff, func(error) retry.Action { return retry.Success },
+			func() error {
+				got = rtr.FullJitterBackoff(tc.attempt, tc.first, tc.limit)
+				return nil
+			})
+
+		if got < 0 || got > ceiling {
+			t.Errorf("FullJitterBackoff(%d, %s, %s) = %s, want in [0, %s]",
+				tc.attempt, tc.first, tc.limit, got, ceiling)
+		}
+	}
+
+	testCases := []testCase{
+		{name: "first attempt", attempt: 0, first: time.Second, limit: time.Minute},
+		{name: "third attempt", attempt: 2, first: time.Second, limit: time.Minute},
+		{name: "past the limit", attempt: 10, first: time.Second, limit: time.Minute},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) { run(t, tc) })
+	}
+}
+
+func TestDecorrelatedJitterBackoffWithinBounds(t *testing.T) {
+	first := time.Second
+	limit := 30 * time.Second
+
+	rtr := retry.Retry{Rand: rand.New(rand.NewSource(7))}
+
+	backoff := rtr.NewDecorrelatedJitterBackoff()
+	prev := first
+	for attempt := 0; attempt < 10; attempt++ {
+		delay := backoff(attempt, first, limit)
+		if delay < first || delay > limit {
+			t.Fatalf("attempt %d: delay %s out of bounds [%s, %s]",
+				attempt, delay, first, limit)
+		}
+		if delay > prev*3 {
+			t.Fatalf("attempt %d: delay %s exceeds 3x previous delay %s",
+				attempt, delay, prev)
+		}
+		prev = delay
+	}
+}
+
+func TestDecorrelatedJitterBackoffDoesNotPanicWhenLimitIsTooSmall(t *testing.T) {
+	// limit*3 <= first, so once the first delay is capped at limit, the next
+	// call would compute prev*3-first <= 0: a misconfiguration that must
+	// degrade gracefully rather than panic in Int63n.
+	first := 10 * time.Second
+	limit := 2 * time.Second
+
+	rtr := retry.Retry{Rand: rand.New(rand.NewSource(1))}
+	backoff := rtr.NewDecorrelatedJitterBackoff()
+
+	for attempt := 0; attempt < 5; attempt++ {
+		delay := backoff(attempt, first, limit)
+		if delay > limit {
+			t.Fatalf("attempt %d: delay %s exceeds limit %s", attempt, delay, limit)
+		}
+	}
+}
+
+func TestJitterBackoffsConcurrentUseHasNoDataRace(t *testing.T) {
+	first := time.Second
+	limit := time.Minute
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		rtr := retry.Retry{}
+		decorrelated := rtr.NewDecorrelatedJitterBackoff()
+
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for attempt := 0; attempt < 10; attempt++ {
+				rtr.FullJitterBackoff(attempt, first, limit)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for attempt := 0; attempt < 10; attempt++ {
+				decorrelated(attempt, first, limit)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDoContextPassesContextToWork(t *testing.T) {
+	rtr := retry.Retry{
+		UpTo:         5 * time.Second,
+		FirstDelay:   1 * time.Second,
+		BackoffLimit: 1 * time.Minute,
+		Log:          makeLog(),
+		SleepFn:      func(time.Duration) {},
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "hello")
+
+	var got any
+	workFn := func(ctx context.Context) error {
+		got = ctx.Value(ctxKey{})
+		return nil
+	}
+
+	if err := rtr.DoContext(ctx, retry.ConstantBackoff, retryOnError, workFn); err != nil {
+		t.Fatalf("%s:\nhave: %v\nwant: %v", "retry.DoContext", err, "<no error>")
+	}
+	if have, want := got, "hello"; have != want {
+		t.Errorf("%s:\nhave: %v\nwant: %v", "context value seen by work", have, want)
+	}
+}
+
+func TestDoContextAbortsOnCancellation(t *testing.T) {
+	rtr := retry.Retry{
+		UpTo:         1 * time.Minute,
+		FirstDelay:   10 * time.Millisecond,
+		BackoffLimit: 1 * time.Minute,
+		Log:          makeLog(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ErrAlwaysFail := errors.New("I always fail")
+	attempt := 0
+	workFn := func(context.Context) error {
+		attempt++
+		if attempt == 2 {
+			cancel()
+		}
+		return ErrAlwaysFail
+	}
+
+	err := rtr.DoContext(ctx, retry.ConstantBackoff, retryOnError, workFn)
+
+	if !errors.Is(err, ErrAlwaysFail) {
+		t.Errorf("%s:\nhave: %v\nwant: %v", "retry.DoContext", err, ErrAlwaysFail)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("%s:\nhave: %v\nwant: %v", "retry.DoContext", err, context.Canceled)
+	}
+}
+
+func TestDoContextDeadlineCapsUpTo(t *testing.T) {
+	rtr := retry.Retry{
+		UpTo:         1 * time.Hour,
+		FirstDelay:   10 * time.Millisecond,
+		BackoffLimit: 1 * time.Minute,
+		Log:          makeLog(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Millisecond)
+	defer cancel()
+
+	ErrAlwaysFail := errors.New("I always fail")
+	workFn := func(context.Context) error { return ErrAlwaysFail }
+
+	start := time.Now()
+	err := rtr.DoContext(ctx, retry.ConstantBackoff, retryOnError, workFn)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("DoContext took %s, want to be capped by the context deadline", elapsed)
+	}
+	if !errors.Is(err, ErrAlwaysFail) {
+		t.Errorf("%s:\nhave: %v\nwant: %v", "retry.DoContext", err, ErrAlwaysFail)
+	}
+}
+
+type ctxKey struct{}
+
 func retryOnError(err error) retry.Action {
 	if err != nil {
 		return retry.SoftFail